@@ -0,0 +1,215 @@
+package tdigest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// mergingBufferRatio controls how many unprocessed centroids are buffered
+// (relative to the compression factor) before they are folded into the
+// processed set.
+const mergingBufferRatio = 5
+
+// MergingDigest is a quantile summary structure implementing Ted
+// Dunning's merging variant of the t-digest algorithm. Unlike TDigest,
+// which re-balances a tree on every Add, MergingDigest buffers incoming
+// samples and periodically merges them into a sorted, already-compressed
+// slice of centroids, trading a small amount of read-time latency for
+// higher ingestion throughput.
+type MergingDigest struct {
+	compression float64
+	processed   []centroid
+	unprocessed []centroid
+	bufferSize  int
+	count       uint32
+}
+
+// NewMerging creates a new merging digest.
+// The compression parameter has the same meaning as in New: it rules
+// the threshold in which samples are merged together, trading off
+// precision for a smaller number of retained centroids.
+func NewMerging(compression float64) *MergingDigest {
+	bufferSize := int(mergingBufferRatio*compression) + 10
+	return &MergingDigest{
+		compression: compression,
+		processed:   make([]centroid, 0, int(compression)),
+		unprocessed: make([]centroid, 0, bufferSize),
+		bufferSize:  bufferSize,
+	}
+}
+
+// Add registers a new sample in the digest.
+// It's the main entry point for the digest and very likely the only
+// method to be used for collecting samples. The count parameter is for
+// when you are registering a sample that occurred multiple times - the
+// most common value for this is 1.
+func (m *MergingDigest) Add(value float64, count uint32) error {
+	if count == 0 {
+		return fmt.Errorf("Illegal datapoint <value: %.4f, count: %d>", value, count)
+	}
+
+	m.unprocessed = append(m.unprocessed, centroid{mean: value, count: count})
+	m.count += count
+
+	if len(m.unprocessed) >= m.bufferSize {
+		m.flush()
+	}
+
+	return nil
+}
+
+// flush merges the buffered unprocessed centroids into the processed
+// set, keeping the result sorted by mean and bounded by the usual
+// t-digest centroid size limit.
+func (m *MergingDigest) flush() {
+	if len(m.unprocessed) == 0 {
+		return
+	}
+
+	sort.Slice(m.unprocessed, func(i, j int) bool {
+		return m.unprocessed[i].mean < m.unprocessed[j].mean
+	})
+
+	merged := make([]centroid, 0, len(m.processed)+len(m.unprocessed))
+	totalWeight := float64(m.count)
+	var cumBefore uint32
+	var open *centroid
+
+	pi, ui := 0, 0
+	for pi < len(m.processed) || ui < len(m.unprocessed) {
+		var next centroid
+		if pi < len(m.processed) && (ui >= len(m.unprocessed) || m.processed[pi].mean <= m.unprocessed[ui].mean) {
+			next = m.processed[pi]
+			pi++
+		} else {
+			next = m.unprocessed[ui]
+			ui++
+		}
+
+		if open == nil {
+			o := next
+			open = &o
+			continue
+		}
+
+		combined := open.count + next.count
+		q := (float64(cumBefore) + float64(combined)/2.0) / totalWeight
+		bound := 4 * totalWeight * q * (1 - q) / m.compression
+
+		if float64(combined) <= bound {
+			open.Update(next.mean, next.count)
+		} else {
+			cumBefore += open.count
+			merged = append(merged, *open)
+			o := next
+			open = &o
+		}
+	}
+
+	if open != nil {
+		merged = append(merged, *open)
+	}
+
+	m.processed = merged
+	m.unprocessed = m.unprocessed[:0]
+}
+
+// Quantile returns the desired quantile estimation.
+// Values of q must be between 0 and 1 (inclusive), will panic otherwise.
+func (m *MergingDigest) Quantile(q float64) float64 {
+	if q < 0 || q > 1 {
+		panic("Quantiles must be between 0 and 1 (inclusive)")
+	}
+
+	m.flush()
+
+	if len(m.processed) == 0 {
+		return math.NaN()
+	} else if len(m.processed) == 1 {
+		return m.processed[0].mean
+	}
+
+	p := q * float64(m.count)
+	lastIdx := len(m.processed) - 1
+
+	if p <= float64(m.processed[0].count)/2 {
+		return m.processed[0].mean
+	}
+	if p >= float64(m.count)-float64(m.processed[lastIdx].count)/2 {
+		return m.processed[lastIdx].mean
+	}
+
+	var cum float64
+	for i := 0; i < lastIdx; i++ {
+		c, next := m.processed[i], m.processed[i+1]
+		w1 := cum + float64(c.count)/2
+		w2 := cum + float64(c.count) + float64(next.count)/2
+
+		if p >= w1 && p <= w2 {
+			frac := (p - w1) / (w2 - w1)
+			return c.mean + frac*(next.mean-c.mean)
+		}
+
+		cum += float64(c.count)
+	}
+
+	return m.processed[lastIdx].mean
+}
+
+// CDF returns the fraction of samples that are <= x.
+// The result is always within [0, 1], returning NaN on an empty digest.
+func (m *MergingDigest) CDF(x float64) float64 {
+	m.flush()
+
+	if len(m.processed) == 0 {
+		return math.NaN()
+	}
+
+	if x < m.processed[0].mean {
+		return 0
+	}
+	if x > m.processed[len(m.processed)-1].mean {
+		return 1
+	}
+
+	var cum float64
+	for i := 0; i < len(m.processed)-1; i++ {
+		c, next := m.processed[i], m.processed[i+1]
+
+		if x >= c.mean && x <= next.mean {
+			w1 := cum + float64(c.count)/2
+			w2 := cum + float64(c.count) + float64(next.count)/2
+			frac := (x - c.mean) / (next.mean - c.mean)
+			return (w1 + frac*(w2-w1)) / float64(m.count)
+		}
+
+		cum += float64(c.count)
+	}
+
+	return 1
+}
+
+// Merge joins a given merging digest into itself.
+// Merging is useful when you have multiple MergingDigest instances
+// running in separate threads and you want to compute quantiles over
+// all the samples, such as in a scatter-gather/map-reduce scenario.
+func (m *MergingDigest) Merge(other *MergingDigest) {
+	other.flush()
+
+	if len(other.processed) == 0 {
+		return
+	}
+
+	m.unprocessed = append(m.unprocessed, other.processed...)
+	m.count += other.count
+
+	// other.processed can be arbitrarily larger than bufferSize, growing
+	// unprocessed's backing array well past its configured capacity, so
+	// always flush here rather than gating on a buffer-size comparison.
+	m.flush()
+}
+
+func (m MergingDigest) String() string {
+	return fmt.Sprintf("MD<compression=%.2f, count=%d, centroids=%d>", m.compression, m.count, len(m.processed))
+}
@@ -0,0 +1,208 @@
+package tdigest
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestMergingUniformDistribution(t *testing.T) {
+	t.Parallel()
+
+	digest := NewMerging(100)
+
+	for i := 0; i < 10000; i++ {
+		digest.Add(rand.Float64(), 1)
+	}
+
+	assertMergingDifferenceSmallerThan(digest, 0.5, 0.02, t)
+	assertMergingDifferenceSmallerThan(digest, 0.1, 0.01, t)
+	assertMergingDifferenceSmallerThan(digest, 0.9, 0.01, t)
+	assertMergingDifferenceSmallerThan(digest, 0.01, 0.005, t)
+	assertMergingDifferenceSmallerThan(digest, 0.99, 0.005, t)
+}
+
+func assertMergingDifferenceSmallerThan(digest *MergingDigest, q float64, m float64, t *testing.T) {
+	tq := digest.Quantile(q)
+	if math.Abs(tq-q) >= m {
+		t.Errorf("MergingDigest.Quantile(%.4f) = %.4f. Diff (%.4f) >= %.4f", q, tq, math.Abs(tq-q), m)
+	}
+}
+
+func TestMergingEmpty(t *testing.T) {
+	t.Parallel()
+
+	digest := NewMerging(100)
+
+	if !math.IsNaN(digest.Quantile(0.5)) {
+		t.Errorf("Quantile() on an empty digest should return NaN. Got: %.4f", digest.Quantile(0.5))
+	}
+
+	if !math.IsNaN(digest.CDF(0.5)) {
+		t.Errorf("CDF() on an empty digest should return NaN. Got: %.4f", digest.CDF(0.5))
+	}
+}
+
+func TestMergingAddError(t *testing.T) {
+	t.Parallel()
+
+	digest := NewMerging(100)
+
+	if err := digest.Add(0, 0); err == nil {
+		t.Errorf("Expected Add() to error out with input (0,0)")
+	}
+}
+
+func TestMergingCDF(t *testing.T) {
+	t.Parallel()
+
+	digest := NewMerging(100)
+
+	for i := 0; i < 10000; i++ {
+		digest.Add(rand.Float64(), 1)
+	}
+
+	for _, q := range []float64{0.1, 0.25, 0.5, 0.75, 0.9} {
+		x := digest.Quantile(q)
+		cdf := digest.CDF(x)
+		if math.Abs(cdf-q) >= 0.02 {
+			t.Errorf("CDF(Quantile(%.4f)) = %.4f, expected close to %.4f", q, cdf, q)
+		}
+	}
+}
+
+func TestMergingQuantileMatchesTDigestPercentile(t *testing.T) {
+	t.Parallel()
+
+	td := New(100)
+	digest := NewMerging(100)
+
+	digest.processed = make([]centroid, 10)
+	for i := 0; i < 10; i++ {
+		c := centroid{mean: float64(i), count: 10}
+		digest.processed[i] = c
+		td.addCentroid(&centroid{mean: c.mean, count: c.count})
+	}
+	digest.count = 100
+	td.count = 100
+	td.min, td.max = 0, 9
+
+	for q := 0.05; q < 1; q += 0.05 {
+		tp := td.Percentile(q)
+		mq := digest.Quantile(q)
+		if math.Abs(tp-mq) >= 1e-9 {
+			t.Errorf("Quantile(%.2f) = %.4f, TDigest.Percentile(%.2f) = %.4f; expected them to agree on identical centroid data", q, mq, q, tp)
+		}
+	}
+}
+
+func TestMergingMerge(t *testing.T) {
+	t.Parallel()
+
+	const numItems = 10000
+	const numSubs = 5
+
+	data := make([]float64, numItems)
+	var subs [numSubs]*MergingDigest
+
+	for i := 0; i < numSubs; i++ {
+		subs[i] = NewMerging(10)
+	}
+
+	for i := 0; i < numItems; i++ {
+		num := rand.Float64()
+		data[i] = num
+		for j := 0; j < numSubs; j++ {
+			subs[j].Add(num, 1)
+		}
+	}
+
+	merged := NewMerging(10)
+	for i := 0; i < numSubs; i++ {
+		merged.Merge(subs[i])
+	}
+
+	// Merge empty. Should be a no-op.
+	merged.Merge(NewMerging(10))
+
+	sort.Float64s(data)
+
+	for _, p := range []float64{0.1, 0.5, 0.9} {
+		q := quantile(p, data)
+		mq := merged.Quantile(p)
+
+		if math.Abs(mq-q) >= 0.03 {
+			t.Errorf("Absolute error for %f above threshold. q=%f mq=%f", p, q, mq)
+		}
+	}
+}
+
+func TestMergingKeepsBufferBounded(t *testing.T) {
+	t.Parallel()
+
+	// Build an "other" digest with far more processed centroids than
+	// a's buffer size, so merging it in forces unprocessed's backing
+	// array to grow well past bufferSize.
+	other := NewMerging(5)
+	other.processed = make([]centroid, 200)
+	for i := range other.processed {
+		other.processed[i] = centroid{mean: float64(i), count: 1}
+	}
+	other.count = uint32(len(other.processed))
+
+	a := NewMerging(5)
+	a.Merge(other)
+
+	if len(a.unprocessed) != 0 {
+		t.Errorf("Merge should flush immediately, got %d unprocessed centroids left over", len(a.unprocessed))
+	}
+	if cap(a.unprocessed) <= a.bufferSize {
+		t.Fatalf("test setup didn't grow unprocessed's capacity past bufferSize (%d), got cap=%d", a.bufferSize, cap(a.unprocessed))
+	}
+
+	for i := 0; i < a.bufferSize-1; i++ {
+		a.Add(rand.Float64(), 1)
+	}
+	if len(a.unprocessed) != a.bufferSize-1 {
+		t.Errorf("Expected %d buffered samples before reaching bufferSize, got %d", a.bufferSize-1, len(a.unprocessed))
+	}
+
+	a.Add(rand.Float64(), 1)
+
+	if len(a.unprocessed) != 0 {
+		t.Errorf("Buffer should flush once it reaches bufferSize (%d) regardless of its grown capacity, got %d unprocessed", a.bufferSize, len(a.unprocessed))
+	}
+}
+
+func benchmarkMergingAdd(compression float64, b *testing.B) {
+	digest := NewMerging(compression)
+	for n := 0; n < b.N; n++ {
+		err := digest.Add(rand.Float64(), 1)
+		if err != nil {
+			b.Error(err)
+		}
+	}
+}
+
+func BenchmarkMergingAdd1(b *testing.B) {
+	benchmarkMergingAdd(1, b)
+}
+
+func BenchmarkMergingAdd10(b *testing.B) {
+	benchmarkMergingAdd(10, b)
+}
+
+func BenchmarkMergingAdd100(b *testing.B) {
+	benchmarkMergingAdd(100, b)
+}
+
+func BenchmarkMergingAddSkewed(b *testing.B) {
+	digest := NewMerging(100)
+	for n := 0; n < b.N; n++ {
+		err := digest.Add(rand.ExpFloat64(), 1)
+		if err != nil {
+			b.Error(err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package tdigest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Serialization format versions. Version 1 only stores the compression
+// factor and the (mean, count) pairs for every centroid. Version 2 adds
+// the explicit min/max, which let Percentile return exact extremes
+// instead of relying on the outermost centroid means.
+const (
+	smallEncoding           int32 = 1
+	smallEncodingWithMinMax int32 = 2
+)
+
+func encodeUint(buf *bytes.Buffer, n uint32) {
+	var b [binary.MaxVarintLen64]byte
+	l := binary.PutUvarint(b[:], uint64(n))
+	buf.Write(b[:l])
+}
+
+func decodeUint(buf io.ByteReader) (uint32, error) {
+	n, err := binary.ReadUvarint(buf)
+	return uint32(n), err
+}
+
+// AsBytes serializes the digest into a binary format that can later be
+// restored with FromBytes.
+func (t *TDigest) AsBytes() ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	if err := binary.Write(buf, binary.BigEndian, smallEncodingWithMinMax); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(buf, binary.BigEndian, t.compression); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(buf, binary.BigEndian, t.min); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(buf, binary.BigEndian, t.max); err != nil {
+		return nil, err
+	}
+
+	encodeUint(buf, uint32(t.summary.Len()))
+
+	var err error
+	t.summary.IterInOrderWith(func(item interface{}) bool {
+		c := item.(*centroid)
+		if err = binary.Write(buf, binary.BigEndian, c.mean); err != nil {
+			return false
+		}
+		encodeUint(buf, c.count)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// FromBytes reads a digest serialized with AsBytes back into memory.
+// Both the current (with min/max) and the older, pre-min/max encoding
+// are accepted; digests decoded from the older format fall back to
+// deriving min/max from the outermost centroid means.
+func FromBytes(buf io.Reader) (*TDigest, error) {
+	var encoding int32
+	if err := binary.Read(buf, binary.BigEndian, &encoding); err != nil {
+		return nil, err
+	}
+
+	if encoding != smallEncoding && encoding != smallEncodingWithMinMax {
+		return nil, fmt.Errorf("Unsupported encoding version: %d", encoding)
+	}
+
+	var compression float64
+	if err := binary.Read(buf, binary.BigEndian, &compression); err != nil {
+		return nil, err
+	}
+
+	t := New(compression)
+
+	if encoding == smallEncodingWithMinMax {
+		if err := binary.Read(buf, binary.BigEndian, &t.min); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(buf, binary.BigEndian, &t.max); err != nil {
+			return nil, err
+		}
+	}
+
+	br, ok := buf.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(buf)
+	}
+
+	numCentroids, err := decodeUint(br)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := uint32(0); i < numCentroids; i++ {
+		var mean float64
+		if err := binary.Read(buf, binary.BigEndian, &mean); err != nil {
+			return nil, err
+		}
+		count, err := decodeUint(br)
+		if err != nil {
+			return nil, err
+		}
+
+		t.addCentroid(newCentroid(mean, count))
+		t.count += count
+
+		if encoding == smallEncoding {
+			if mean < t.min {
+				t.min = mean
+			}
+			if mean > t.max {
+				t.max = mean
+			}
+		}
+	}
+
+	return t, nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"sort"
 )
 
 type centroid struct {
@@ -46,6 +47,7 @@ type TDigest struct {
 	summary     *summary
 	compression float64
 	count       uint32
+	min, max    float64
 }
 
 // New creates a new digest.
@@ -56,12 +58,34 @@ type TDigest struct {
 // compression value means holding more centroids in memory, which means
 // a bigger serialization payload and higher memory footprint.
 func New(compression float64) *TDigest {
-	tdigest := TDigest{compression: compression, summary: newSummary(uint(compression) * 20), count: 0}
+	tdigest := TDigest{
+		compression: compression,
+		summary:     newSummary(uint(compression) * 20),
+		count:       0,
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}
 	return &tdigest
 }
 
+// Min returns the smallest value ever registered in the digest, either
+// through Add or Merge. Returns +Inf on an empty digest.
+func (t *TDigest) Min() float64 {
+	return t.min
+}
+
+// Max returns the largest value ever registered in the digest, either
+// through Add or Merge. Returns -Inf on an empty digest.
+func (t *TDigest) Max() float64 {
+	return t.max
+}
+
 // Percentile returns the desired percentile estimation.
 // Values of p must be between 0 and 1 (inclusive), will panic otherwise.
+// The true observed min and max are used as interpolation anchors for
+// the tails, so Percentile(0) and Percentile(1) return the exact
+// extremes ever registered in the digest. A digest holding a single
+// centroid is the exception: it returns that centroid's mean for every p.
 func (t *TDigest) Percentile(p float64) float64 {
 	if p < 0 || p > 1 {
 		panic("Percentiles must be between 0 and 1 (inclusive)")
@@ -74,37 +98,127 @@ func (t *TDigest) Percentile(p float64) float64 {
 	}
 
 	p *= float64(t.count)
-	var total float64
-	i := 0
 
+	var cum, prevMidCum float64
+	prevMean := t.min
 	found := false
 	var result float64
 
 	t.summary.IterInOrderWith(func(item interface{}) bool {
-		k := float64(item.(*centroid).count)
-
-		if p < total+k {
-			if i == 0 || i+1 == t.summary.Len() {
-				result = item.(*centroid).mean
-				found = true
-				return false
-			}
-			succ, pred := t.successorAndPredecessorItems(item.(*centroid))
-			delta := (succ.mean - pred.mean) / 2
-			result = item.(*centroid).mean + ((p-total)/k-0.5)*delta
+		c := item.(*centroid)
+		k := float64(c.count)
+		midCum := cum + k/2
+
+		if p <= midCum {
+			frac := (p - prevMidCum) / (midCum - prevMidCum)
+			result = prevMean + frac*(c.mean-prevMean)
 			found = true
 			return false
 		}
 
-		i++
-		total += k
+		prevMidCum = midCum
+		prevMean = c.mean
+		cum += k
 		return true
 	})
 
 	if found {
 		return result
 	}
-	return t.summary.Max().mean
+
+	frac := (p - prevMidCum) / (float64(t.count) - prevMidCum)
+	return prevMean + frac*(t.max-prevMean)
+}
+
+// CDF returns the fraction of samples that are <= x.
+// It's the counterpart to Percentile: CDF(Percentile(p)) is approximately
+// p, within the digest's usual error bounds. The centroids' means are
+// used as interpolation anchors, with min and max as the endpoints, so
+// CDF(min) is 0 and CDF(max) is 1.
+func (t *TDigest) CDF(x float64) float64 {
+	if t.summary.Len() == 0 {
+		return math.NaN()
+	}
+
+	if x <= t.min {
+		return 0
+	}
+	if x >= t.max {
+		return 1
+	}
+	if t.summary.Len() == 1 {
+		return 0.5
+	}
+
+	var cum, prevMidCum float64
+	prevMean := t.min
+	found := false
+	var result float64
+
+	t.summary.IterInOrderWith(func(item interface{}) bool {
+		c := item.(*centroid)
+		k := float64(c.count)
+		midCum := cum + k/2
+
+		if x <= c.mean {
+			frac := (x - prevMean) / (c.mean - prevMean)
+			result = (prevMidCum + frac*(midCum-prevMidCum)) / float64(t.count)
+			found = true
+			return false
+		}
+
+		prevMidCum = midCum
+		prevMean = c.mean
+		cum += k
+		return true
+	})
+
+	if found {
+		return result
+	}
+
+	frac := (x - prevMean) / (t.max - prevMean)
+	return (prevMidCum + frac*(float64(t.count)-prevMidCum)) / float64(t.count)
+}
+
+// TrimmedMean returns the weighted mean of samples whose rank fraction
+// falls in [lo, hi], e.g. TrimmedMean(0.1, 0.9) discards the bottom and
+// top 10% of the distribution before averaging. Values of lo and hi
+// must satisfy 0 <= lo < hi <= 1, will panic otherwise.
+func (t *TDigest) TrimmedMean(lo, hi float64) float64 {
+	if lo < 0 || hi > 1 || lo >= hi {
+		panic("TrimmedMean bounds must satisfy 0 <= lo < hi <= 1")
+	}
+
+	if t.summary.Len() == 0 {
+		return math.NaN()
+	}
+
+	loCount := lo * float64(t.count)
+	hiCount := hi * float64(t.count)
+
+	var cum, weightedSum, totalWeight float64
+
+	t.summary.IterInOrderWith(func(item interface{}) bool {
+		c := item.(*centroid)
+		k := float64(c.count)
+
+		lower := math.Max(cum, loCount)
+		upper := math.Min(cum+k, hiCount)
+		if upper > lower {
+			overlap := upper - lower
+			weightedSum += overlap * c.mean
+			totalWeight += overlap
+		}
+
+		cum += k
+		return true
+	})
+
+	if totalWeight == 0 {
+		return math.NaN()
+	}
+	return weightedSum / totalWeight
 }
 
 // Add registers a new sample in the digest.
@@ -120,6 +234,13 @@ func (t *TDigest) Add(value float64, count uint32) error {
 
 	t.count += count
 
+	if value < t.min {
+		t.min = value
+	}
+	if value > t.max {
+		t.max = value
+	}
+
 	c := newCentroid(value, count)
 
 	if t.summary.Len() == 0 {
@@ -183,18 +304,153 @@ func (t *TDigest) Compress() {
 // Merging is useful when you have multiple TDigest instances running
 // in separate threads and you want to compute quantiles over all the
 // samples. This is particularly important on a scatter-gather/map-reduce
-// scenario.
+// scenario. The two centroid sets are combined and folded together in a
+// single left-to-right pass, using the same size bound as Add.
 func (t *TDigest) Merge(other *TDigest) {
 	if other.summary.Len() == 0 {
 		return
 	}
 
-	nodes := other.summary.Data()
-	shuffle(nodes)
+	if other.min < t.min {
+		t.min = other.min
+	}
+	if other.max > t.max {
+		t.max = other.max
+	}
 
-	for _, item := range nodes {
-		t.Add(item.mean, item.count)
+	nodes := make([]*centroid, 0, t.summary.Len()+other.summary.Len())
+	nodes = append(nodes, t.summary.Data()...)
+	nodes = append(nodes, other.summary.Data()...)
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].mean < nodes[j].mean })
+
+	t.count += other.count
+
+	merged := newSummary(uint(t.compression) * 20)
+	var cumBefore uint32
+	var open *centroid
+
+	for _, next := range nodes {
+		if open == nil {
+			o := *next
+			open = &o
+			continue
+		}
+
+		combined := open.count + next.count
+		quantile := (float64(cumBefore) + float64(combined)/2.0) / float64(t.count)
+
+		if float64(combined) <= t.threshold(quantile) {
+			open.Update(next.mean, next.count)
+		} else {
+			cumBefore += open.count
+			merged.Add(open)
+			o := *next
+			open = &o
+		}
+	}
+
+	if open != nil {
+		merged.Add(open)
+	}
+
+	t.summary = merged
+}
+
+// AddSorted registers a batch of non-decreasing samples in the digest
+// via a single sequential merge pass, which is cheaper than calling Add
+// once per sample. values must be sorted in non-decreasing order;
+// AddSorted always validates this and returns an error otherwise.
+func (t *TDigest) AddSorted(values []float64) error {
+	weights := make([]float64, len(values))
+	for i := range weights {
+		weights[i] = 1
+	}
+	return t.AddWeightedSorted(values, weights)
+}
+
+// AddWeightedSorted is like AddSorted, but for callers that already have
+// (mean, weight) pairs - for example when merging the raw samples of
+// another digest. means must be sorted in non-decreasing order.
+func (t *TDigest) AddWeightedSorted(means, weights []float64) error {
+	if len(means) != len(weights) {
+		return fmt.Errorf("means and weights must have the same length, got %d and %d", len(means), len(weights))
+	}
+
+	if len(means) == 0 {
+		return nil
+	}
+
+	for i := 1; i < len(means); i++ {
+		if means[i] < means[i-1] {
+			return fmt.Errorf("AddWeightedSorted requires non-decreasing input, got %.4f before %.4f", means[i], means[i-1])
+		}
 	}
+
+	for i, w := range weights {
+		if w < 1 || w != math.Trunc(w) {
+			return fmt.Errorf("Illegal datapoint <value: %.4f, weight: %.4f>", means[i], w)
+		}
+	}
+
+	var newCount uint32
+	for _, w := range weights {
+		newCount += uint32(w)
+	}
+
+	for _, mean := range means {
+		if mean < t.min {
+			t.min = mean
+		}
+		if mean > t.max {
+			t.max = mean
+		}
+	}
+
+	existing := t.summary.Data()
+	t.count += newCount
+
+	merged := newSummary(uint(t.compression) * 20)
+	var cumBefore uint32
+	var open *centroid
+
+	ei, ni := 0, 0
+	for ei < len(existing) || ni < len(means) {
+		var mean float64
+		var weight uint32
+
+		if ei < len(existing) && (ni >= len(means) || existing[ei].mean <= means[ni]) {
+			mean, weight = existing[ei].mean, existing[ei].count
+			ei++
+		} else {
+			mean, weight = means[ni], uint32(weights[ni])
+			ni++
+		}
+
+		if open == nil {
+			o := centroid{mean: mean, count: weight}
+			open = &o
+			continue
+		}
+
+		combined := open.count + weight
+		quantile := (float64(cumBefore) + float64(combined)/2.0) / float64(t.count)
+
+		if float64(combined) <= t.threshold(quantile) {
+			open.Update(mean, weight)
+		} else {
+			cumBefore += open.count
+			merged.Add(open)
+			o := centroid{mean: mean, count: weight}
+			open = &o
+		}
+	}
+
+	if open != nil {
+		merged.Add(open)
+	}
+
+	t.summary = merged
+	return nil
 }
 
 func shuffle(data []*centroid) {
@@ -296,8 +552,3 @@ func (t *TDigest) ceilingAndFloorItems(c *centroid) (*centroid, *centroid) {
 	// floor   => greatest key less than or equals to key
 	return t.getSurroundingWith(c, centroidLessOrEquals)
 }
-
-func (t *TDigest) successorAndPredecessorItems(c *centroid) (*centroid, *centroid) {
-	// FIXME This can be way cheaper if done directly on the tree nodes
-	return t.getSurroundingWith(c, centroidLess)
-}
@@ -185,6 +185,59 @@ func TestUniformDistribution(t *testing.T) {
 	assertDifferenceSmallerThan(tdigest, 0.999, 0.001, t)
 }
 
+func assertCDFDifferenceSmallerThan(tdigest *TDigest, x float64, q float64, m float64, t *testing.T) {
+	tq := tdigest.CDF(x)
+	if math.Abs(tq-q) >= m {
+		t.Errorf("T-Digest.CDF(%.4f) = %.4f. Diff (%.4f) >= %.4f", x, tq, math.Abs(tq-q), m)
+	}
+}
+
+func TestCDF(t *testing.T) {
+	t.Parallel()
+
+	tdigest := New(100)
+
+	if !math.IsNaN(tdigest.CDF(0.5)) {
+		t.Errorf("CDF() on an empty digest should return NaN. Got: %.4f", tdigest.CDF(0.5))
+	}
+
+	for i := 0; i < 10000; i++ {
+		tdigest.Add(rand.Float64(), 1)
+	}
+
+	assertCDFDifferenceSmallerThan(tdigest, 0.5, 0.5, 0.02, t)
+	assertCDFDifferenceSmallerThan(tdigest, 0.1, 0.1, 0.01, t)
+	assertCDFDifferenceSmallerThan(tdigest, 0.9, 0.9, 0.01, t)
+	assertCDFDifferenceSmallerThan(tdigest, 0.01, 0.01, 0.005, t)
+	assertCDFDifferenceSmallerThan(tdigest, 0.99, 0.99, 0.005, t)
+
+	for _, p := range []float64{0.1, 0.25, 0.5, 0.75, 0.9} {
+		x := tdigest.Percentile(p)
+		if cdf := tdigest.CDF(x); math.Abs(cdf-p) >= 0.02 {
+			t.Errorf("CDF(Percentile(%.4f)) = %.4f, expected close to %.4f", p, cdf, p)
+		}
+	}
+}
+
+func TestCDFUpperTailWithOutlier(t *testing.T) {
+	t.Parallel()
+
+	tdigest := New(5)
+	for i := 0; i < 2000; i++ {
+		tdigest.Add(float64(i), 1)
+	}
+	tdigest.Add(1e6, 1)
+
+	lastMean := tdigest.summary.Max().mean
+	if cdf := tdigest.CDF(lastMean + 1); cdf >= 2000.0/2001.0 {
+		t.Errorf("CDF(%.4f) = %.4f, expected < %.4f since the true max (%.0f) is still above it", lastMean+1, cdf, 2000.0/2001.0, tdigest.Max())
+	}
+
+	if cdf := tdigest.CDF(tdigest.Max()); cdf != 1 {
+		t.Errorf("CDF(Max()) should be exactly 1, got %.4f", cdf)
+	}
+}
+
 func TestSequentialInsertion(t *testing.T) {
 	t.Parallel()
 	tdigest := New(10)
@@ -195,6 +248,102 @@ func TestSequentialInsertion(t *testing.T) {
 	}
 }
 
+func TestAddSorted(t *testing.T) {
+	t.Parallel()
+	tdigest := New(10)
+
+	values := make([]float64, 10000)
+	for i := range values {
+		values[i] = float64(i)
+	}
+
+	if err := tdigest.AddSorted(values); err != nil {
+		t.Errorf("AddSorted on a sorted slice should not error out. Got: %s", err)
+	}
+
+	if tdigest.Min() != 0 || tdigest.Max() != float64(len(values)-1) {
+		t.Errorf("Expected Min/Max to be 0/%d, got %f/%f", len(values)-1, tdigest.Min(), tdigest.Max())
+	}
+
+	n := float64(len(values) - 1)
+	for _, p := range []float64{0.1, 0.5, 0.9} {
+		expected := p * n
+		got := tdigest.Percentile(p)
+		if math.Abs(got-expected) >= 0.02*n {
+			t.Errorf("Percentile(%.2f) = %.4f, expected close to %.4f", p, got, expected)
+		}
+	}
+}
+
+func TestAddSortedRejectsUnsortedInput(t *testing.T) {
+	t.Parallel()
+	tdigest := New(10)
+
+	if err := tdigest.AddSorted([]float64{3, 1, 2}); err == nil {
+		t.Errorf("Expected AddSorted to error out on unsorted input")
+	}
+}
+
+func TestAddWeightedSortedMismatchedLengths(t *testing.T) {
+	t.Parallel()
+	tdigest := New(10)
+
+	if err := tdigest.AddWeightedSorted([]float64{1, 2}, []float64{1}); err == nil {
+		t.Errorf("Expected AddWeightedSorted to error out on mismatched slice lengths")
+	}
+}
+
+func TestAddWeightedSortedRejectsSubUnitWeights(t *testing.T) {
+	t.Parallel()
+	tdigest := New(10)
+
+	if err := tdigest.AddWeightedSorted([]float64{1, 2, 3}, []float64{0.9, 0.9, 0.9}); err == nil {
+		t.Errorf("Expected AddWeightedSorted to error out on sub-unit weights")
+	}
+
+	if err := tdigest.AddWeightedSorted([]float64{1, 2, 3}, []float64{1, 1.5, 1}); err == nil {
+		t.Errorf("Expected AddWeightedSorted to error out on non-integral weights")
+	}
+
+	if tdigest.count != 0 || tdigest.summary.Len() != 0 {
+		t.Errorf("Rejected batches should not leave partial state behind. count=%d, len=%d", tdigest.count, tdigest.summary.Len())
+	}
+}
+
+func TestAddSortedMergesIntoExistingData(t *testing.T) {
+	t.Parallel()
+	tdigest := New(100)
+
+	for i := 0; i < 50; i++ {
+		tdigest.Add(float64(i*2), 1)
+	}
+
+	odds := make([]float64, 50)
+	for i := range odds {
+		odds[i] = float64(i*2 + 1)
+	}
+
+	if err := tdigest.AddSorted(odds); err != nil {
+		t.Errorf("AddSorted into a populated digest should not error out. Got: %s", err)
+	}
+
+	if tdigest.count != 100 {
+		t.Errorf("Expected count to be 100, got %d", tdigest.count)
+	}
+	if tdigest.Min() != 0 || tdigest.Max() != 99 {
+		t.Errorf("Expected Min/Max to be 0/99, got %f/%f", tdigest.Min(), tdigest.Max())
+	}
+
+	n := 99.0
+	for _, p := range []float64{0.1, 0.5, 0.9} {
+		expected := p * n
+		got := tdigest.Percentile(p)
+		if math.Abs(got-expected) >= 0.05*n {
+			t.Errorf("Percentile(%.2f) = %.4f, expected close to %.4f", p, got, expected)
+		}
+	}
+}
+
 func TestIntegers(t *testing.T) {
 	t.Parallel()
 	tdigest := New(100)
@@ -228,6 +377,60 @@ func TestIntegers(t *testing.T) {
 	}
 }
 
+func exactTrimmedMean(lo, hi float64, data []float64) float64 {
+	n := float64(len(data))
+	var sum, weight float64
+
+	for i, v := range data {
+		lower := math.Max(float64(i), lo*n)
+		upper := math.Min(float64(i+1), hi*n)
+		if upper > lower {
+			overlap := upper - lower
+			sum += overlap * v
+			weight += overlap
+		}
+	}
+
+	return sum / weight
+}
+
+func TestTrimmedMean(t *testing.T) {
+	t.Parallel()
+
+	tdigest := New(100)
+
+	if !math.IsNaN(tdigest.TrimmedMean(0.1, 0.9)) {
+		t.Errorf("TrimmedMean() on an empty digest should return NaN. Got: %.4f", tdigest.TrimmedMean(0.1, 0.9))
+	}
+
+	data := make([]float64, 10000)
+	for i := range data {
+		data[i] = rand.Float64()
+		tdigest.Add(data[i], 1)
+	}
+	sort.Float64s(data)
+
+	for _, bounds := range [][2]float64{{0, 1}, {0.1, 0.9}, {0.25, 0.75}, {0.5, 0.51}} {
+		expected := exactTrimmedMean(bounds[0], bounds[1], data)
+		got := tdigest.TrimmedMean(bounds[0], bounds[1])
+		if math.Abs(got-expected) >= 0.01 {
+			t.Errorf("TrimmedMean(%.2f, %.2f) = %.4f, expected close to %.4f", bounds[0], bounds[1], got, expected)
+		}
+	}
+}
+
+func TestTrimmedMeanPanicsOnInvalidBounds(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected TrimmedMean to panic on invalid bounds")
+		}
+	}()
+
+	New(100).TrimmedMean(0.9, 0.1)
+}
+
 func quantile(q float64, data []float64) float64 {
 	if len(data) == 0 {
 		return math.NaN()
@@ -331,6 +534,38 @@ func TestSerialization(t *testing.T) {
 	if t1.count != t2.count || t1.summary.Len() != t2.summary.Len() || t1.compression != t2.compression {
 		t.Errorf("Deserialized to something different. t1=%s t2=%s serialized=%x", t1, t2, serialized)
 	}
+
+	if t1.Min() != t2.Min() || t1.Max() != t2.Max() {
+		t.Errorf("Min/Max didn't survive serialization. t1=[%f,%f] t2=[%f,%f]", t1.Min(), t1.Max(), t2.Min(), t2.Max())
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	t.Parallel()
+
+	tdigest := New(100)
+
+	if !math.IsInf(tdigest.Min(), 1) || !math.IsInf(tdigest.Max(), -1) {
+		t.Errorf("Min/Max on an empty digest should be +Inf/-Inf. Got: %f/%f", tdigest.Min(), tdigest.Max())
+	}
+
+	values := []float64{0.4, 0.1, 0.9, 0.5}
+	for _, v := range values {
+		tdigest.Add(v, 1)
+	}
+
+	if tdigest.Min() != 0.1 {
+		t.Errorf("Expected Min() == 0.1, got %f", tdigest.Min())
+	}
+	if tdigest.Max() != 0.9 {
+		t.Errorf("Expected Max() == 0.9, got %f", tdigest.Max())
+	}
+	if tdigest.Percentile(0) != tdigest.Min() {
+		t.Errorf("Percentile(0) should equal Min(). Got %f, want %f", tdigest.Percentile(0), tdigest.Min())
+	}
+	if tdigest.Percentile(1) != tdigest.Max() {
+		t.Errorf("Percentile(1) should equal Max(). Got %f, want %f", tdigest.Percentile(1), tdigest.Max())
+	}
 }
 
 func benchmarkAdd(compression float64, b *testing.B) {
@@ -354,3 +589,35 @@ func BenchmarkAdd10(b *testing.B) {
 func BenchmarkAdd100(b *testing.B) {
 	benchmarkAdd(100, b)
 }
+
+func benchmarkMerge(subCount int, subSize int, b *testing.B) {
+	compression := 100.0
+
+	subs := make([]*TDigest, subCount)
+	for i := range subs {
+		subs[i] = New(compression)
+		for j := 0; j < subSize; j++ {
+			subs[i].Add(rand.Float64(), 1)
+		}
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		dest := New(compression)
+		for _, sub := range subs {
+			dest.Merge(sub)
+		}
+	}
+}
+
+func BenchmarkMerge10x1000(b *testing.B) {
+	benchmarkMerge(10, 1000, b)
+}
+
+func BenchmarkMerge100x1000(b *testing.B) {
+	benchmarkMerge(100, 1000, b)
+}
+
+func BenchmarkMerge10x100000(b *testing.B) {
+	benchmarkMerge(10, 100000, b)
+}